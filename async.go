@@ -0,0 +1,203 @@
+package logging
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy controls what an AsyncWriter does when its buffer is
+// full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the writer until space is available.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered entry to make
+	// room for the new one, and counts it against Stats.Dropped.
+	OverflowDropOldest
+)
+
+// Stats reports counters for an AsyncWriter.
+type Stats struct {
+	Dropped int64
+}
+
+type asyncMsg struct {
+	data []byte
+	done chan struct{}
+}
+
+// AsyncWriter buffers writes on a bounded channel and flushes them to
+// the wrapped io.Writer from a dedicated goroutine, so callers never
+// block on a slow sink.
+type AsyncWriter struct {
+	w      io.Writer
+	ch     chan asyncMsg
+	policy OverflowPolicy
+	onDrop func(dropped int)
+
+	dropped int64
+
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewAsyncWriter wraps output in a bounded, buffered AsyncWriter.
+// bufferSize is the number of entries the channel can hold before
+// policy takes effect. onDrop, if non-nil, is called with the number
+// of newly dropped entries each time OverflowDropOldest discards one.
+func NewAsyncWriter(output io.Writer, bufferSize int, policy OverflowPolicy, onDrop func(dropped int)) *AsyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	w := &AsyncWriter{
+		w:      output,
+		ch:     make(chan asyncMsg, bufferSize),
+		policy: policy,
+		onDrop: onDrop,
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w
+}
+
+func (w *AsyncWriter) run() {
+	defer w.wg.Done()
+
+	for msg := range w.ch {
+		if msg.data != nil {
+			w.w.Write(msg.data)
+		}
+		if msg.done != nil {
+			close(msg.done)
+		}
+	}
+}
+
+// Write buffers p for asynchronous delivery. It never blocks on the
+// underlying writer. Depending on policy, it either blocks until
+// buffer space is available or drops the oldest buffered entry to
+// make room.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	msg := asyncMsg{data: buf}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if w.policy == OverflowBlock {
+		w.ch <- msg
+		return len(p), nil
+	}
+
+	for {
+		select {
+		case w.ch <- msg:
+			return len(p), nil
+		default:
+			select {
+			case evicted := <-w.ch:
+				if evicted.done != nil {
+					// A Flush() sentinel, not a real entry: completing
+					// it here would tell Flush() everything ahead of it
+					// was delivered when some of it may be about to be
+					// dropped instead. Put it back behind what's still
+					// queued and evict a real entry in its place.
+					w.ch <- evicted
+					continue
+				}
+				atomic.AddInt64(&w.dropped, 1)
+				if w.onDrop != nil {
+					w.onDrop(1)
+				}
+			default:
+			}
+		}
+	}
+}
+
+// WriteGuaranteed buffers p for asynchronous delivery like Write, but
+// always blocks for room rather than applying policy, so the message
+// is never discarded. Fatal and Panic use it for their final entry so
+// a subsequent Flush is guaranteed to wait for real delivery rather
+// than for a dropped message.
+func (w *AsyncWriter) WriteGuaranteed(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	msg := asyncMsg{data: buf}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	w.ch <- msg
+	return len(p), nil
+}
+
+// Stats returns the current counters for w.
+func (w *AsyncWriter) Stats() Stats {
+	return Stats{Dropped: atomic.LoadInt64(&w.dropped)}
+}
+
+// Flush blocks until every entry written to w before the call has
+// been delivered to the underlying writer.
+func (w *AsyncWriter) Flush() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return io.ErrClosedPipe
+	}
+
+	done := make(chan struct{})
+	w.ch <- asyncMsg{done: done}
+	w.mu.Unlock()
+
+	<-done
+	return nil
+}
+
+// Close flushes and stops the background goroutine. w must not be
+// written to after Close returns.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.ch)
+	w.mu.Unlock()
+
+	w.wg.Wait()
+
+	if c, ok := w.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// NewAsyncLogger builds a Logger whose writes are buffered on a
+// channel of bufferSize entries and delivered to output from a
+// dedicated goroutine, so Info/Error calls never block on a slow
+// sink. When the buffer is full, the oldest buffered entry is
+// dropped; onDrop, if non-nil, is called with the number dropped.
+// Callers should call logger.Close() (or Flush() before shutdown) to
+// avoid losing buffered entries.
+func NewAsyncLogger(output io.Writer, f Formatter, level string, bufferSize int, onDrop func(dropped int), opts ...Option) Logger {
+	aw := NewAsyncWriter(output, bufferSize, OverflowDropOldest, onDrop)
+	return NewLogger(aw, f, level, opts...)
+}
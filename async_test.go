@@ -0,0 +1,118 @@
+package logging_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rickbassham/logging"
+)
+
+func TestAsyncWriterCloseDuringConcurrentWrites(t *testing.T) {
+	w := logging.NewAsyncWriter(ioutil.Discard, 4, logging.OverflowDropOldest, nil)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					w.Write([]byte("x"))
+				}
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestAsyncWriterWriteGuaranteedSurvivesEvictionPressure(t *testing.T) {
+	buf := &syncBuffer{}
+	w := logging.NewAsyncWriter(buf, 1, logging.OverflowDropOldest, nil)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					w.Write([]byte("x"))
+				}
+			}
+		}()
+	}
+
+	if _, err := w.WriteGuaranteed([]byte("critical\n")); err != nil {
+		t.Fatalf("WriteGuaranteed: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	close(stop)
+	wg.Wait()
+	w.Close()
+
+	if !strings.Contains(buf.String(), "critical") {
+		t.Fatalf("expected the guaranteed write to survive concurrent eviction pressure, got: %q", buf.String())
+	}
+}
+
+func TestAsyncWriterFlushDoesNotHangUnderEviction(t *testing.T) {
+	w := logging.NewAsyncWriter(ioutil.Discard, 1, logging.OverflowDropOldest, nil)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					w.Write([]byte("x"))
+				}
+			}
+		}()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Flush()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Flush hung under write contention")
+	}
+
+	close(stop)
+	wg.Wait()
+	w.Close()
+}
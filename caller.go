@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// pkgPrefix is this package's import path, e.g.
+// "github.com/rickbassham/logging", used to skip over the package's
+// own frames (setData, log, WithError, ...) when resolving the first
+// external caller. It is derived from a named type's PkgPath rather
+// than a function name, since function names for package-scoped
+// closures (like an old `var x = func() {...}()` initializer) are not
+// reliably package-qualified.
+var pkgPrefix = reflect.TypeOf(callInfo{}).PkgPath()
+
+type callInfo struct {
+	packageName string
+	fileName    string
+	funcName    string
+	line        int
+}
+
+// retrieveCallInfo walks the call stack past this package's own
+// frames and returns the first external caller, additionally skipping
+// skip more frames beyond that for callers wrapped in their own
+// helper packages (see WithCallerSkip).
+func retrieveCallInfo(skip int) *callInfo {
+	frames := externalFrames(skip)
+	if len(frames) == 0 {
+		return &callInfo{}
+	}
+
+	return parseFrame(frames[0])
+}
+
+// retrieveStack is like retrieveCallInfo, but returns every frame from
+// the first external caller to the top of the stack, for attaching a
+// full stack trace to an error.
+func retrieveStack(skip int) []errorLocation {
+	frames := externalFrames(skip)
+
+	stack := make([]errorLocation, 0, len(frames))
+	for _, frame := range frames {
+		ci := parseFrame(frame)
+		stack = append(stack, errorLocation{
+			Package:  ci.packageName,
+			Function: ci.funcName,
+			File:     ci.fileName,
+			Line:     ci.line,
+		})
+	}
+
+	return stack
+}
+
+// externalFrames returns every frame above this package's own
+// internal frames, plus skip further frames for wrapper packages.
+func externalFrames(skip int) []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var external []runtime.Frame
+	for {
+		frame, more := frames.Next()
+
+		if !isInternalFrame(frame) {
+			external = append(external, frame)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	if skip >= len(external) {
+		return nil
+	}
+
+	return external[skip:]
+}
+
+func isInternalFrame(frame runtime.Frame) bool {
+	return frame.Function == pkgPrefix || strings.HasPrefix(frame.Function, pkgPrefix+".")
+}
+
+func parseFrame(frame runtime.Frame) *callInfo {
+	_, fileName := path.Split(frame.File)
+	parts := strings.Split(frame.Function, ".")
+	pl := len(parts)
+	packageName := ""
+	funcName := parts[pl-1]
+
+	if pl >= 2 && len(parts[pl-2]) > 0 && parts[pl-2][0] == '(' {
+		funcName = parts[pl-2] + "." + funcName
+		packageName = strings.Join(parts[0:pl-2], ".")
+	} else {
+		packageName = strings.Join(parts[0:pl-1], ".")
+	}
+
+	return &callInfo{
+		packageName: packageName,
+		fileName:    fileName,
+		funcName:    funcName,
+		line:        frame.Line,
+	}
+}
@@ -0,0 +1,94 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rickbassham/logging"
+)
+
+func TestRetrieveCallInfoReportsCallSite(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSONFormatter{}, logging.LogLevelDebug)
+
+	l.Info("hello")
+	_, file, callerLine, _ := runtime.Caller(0)
+	wantLine := callerLine - 1
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if fn, _ := entry["function"].(string); fn != "TestRetrieveCallInfoReportsCallSite" {
+		t.Errorf("function = %q, want TestRetrieveCallInfoReportsCallSite", fn)
+	}
+
+	if got, _ := entry["file"].(string); filepath.Base(got) != filepath.Base(file) {
+		t.Errorf("file = %q, want %q", got, filepath.Base(file))
+	}
+
+	if gotLine, _ := entry["line"].(float64); int(gotLine) != wantLine {
+		t.Errorf("line = %v, want %d", gotLine, wantLine)
+	}
+}
+
+func logViaWrapper(l logging.Logger) {
+	l.Info("hello")
+}
+
+func TestRetrieveCallInfoThroughWrapper(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSONFormatter{}, logging.LogLevelDebug, logging.WithCallerSkip(1))
+
+	logViaWrapper(l)
+	_, _, callerLine, _ := runtime.Caller(0)
+	wantLine := callerLine - 1
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if fn, _ := entry["function"].(string); fn != "TestRetrieveCallInfoThroughWrapper" {
+		t.Errorf("function = %q, want TestRetrieveCallInfoThroughWrapper", fn)
+	}
+
+	if gotLine, _ := entry["line"].(float64); int(gotLine) != wantLine {
+		t.Errorf("line = %v, want %d", gotLine, wantLine)
+	}
+}
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string { return e.msg }
+
+func TestWithErrorStackExcludesInternalFrames(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSONFormatter{}, logging.LogLevelDebug)
+
+	l.WithError(&testError{"boom"}).Error("boom")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	stack, _ := entry["stack"].([]interface{})
+	if len(stack) == 0 {
+		t.Fatalf("expected a non-empty stack")
+	}
+
+	top, _ := stack[0].(map[string]interface{})
+	fn, _ := top["function"].(string)
+	if strings.Contains(fn, "logging.") {
+		t.Errorf("stack[0].function = %q, leaked an internal frame", fn)
+	}
+	if fn != "TestWithErrorStackExcludesInternalFrames" {
+		t.Errorf("stack[0].function = %q, want TestWithErrorStackExcludesInternalFrames", fn)
+	}
+}
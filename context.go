@@ -0,0 +1,78 @@
+package logging
+
+import "context"
+
+type contextKey int
+
+const loggerContextKey contextKey = 0
+
+// NewContext returns a new Context that carries l.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext returns the Logger stored in ctx, if any, along with a
+// boolean indicating whether one was found.
+func FromContext(ctx context.Context) (Logger, bool) {
+	l, ok := ctx.Value(loggerContextKey).(Logger)
+	return l, ok
+}
+
+// WithContext returns a Logger scoped to ctx. If ctx carries a Logger
+// (placed there via NewContext), its fields are inherited and merged
+// in, while l remains the receiver's own writer/formatter/sinks/hooks
+// so request-scoped fields survive across goroutine boundaries and
+// call stacks without losing where the receiver logs to. Fields the
+// receiver already carries take precedence over inherited ones, since
+// they were attached more specifically than the context's parent.
+func (l *logger) WithContext(ctx context.Context) Logger {
+	e := &logEntry{
+		l:      l,
+		Fields: map[string]interface{}{},
+	}
+
+	if parent, ok := FromContext(ctx); ok {
+		if pe, ok := parent.(*logEntry); ok {
+			for k, v := range pe.Fields {
+				if _, exists := e.Fields[k]; !exists {
+					e.Fields[k] = v
+				}
+			}
+
+			if e.ErrorData == nil && pe.ErrorData != nil {
+				e.ErrorData = pe.ErrorData
+				e.ErrorStr = pe.ErrorStr
+				e.Stack = pe.Stack
+			}
+		}
+	}
+
+	return e
+}
+
+// WithContext returns a Logger scoped to ctx, inheriting any fields
+// already attached to l and merging in fields carried by a Logger
+// previously stored on ctx via NewContext. Fields l already carries
+// take precedence over inherited ones, since they were attached more
+// specifically than the context's parent.
+func (l *logEntry) WithContext(ctx context.Context) Logger {
+	e := l.newEntry()
+
+	if parent, ok := FromContext(ctx); ok {
+		if pe, ok := parent.(*logEntry); ok {
+			for k, v := range pe.Fields {
+				if _, exists := e.Fields[k]; !exists {
+					e.Fields[k] = v
+				}
+			}
+
+			if e.ErrorData == nil && pe.ErrorData != nil {
+				e.ErrorData = pe.ErrorData
+				e.ErrorStr = pe.ErrorStr
+				e.Stack = pe.Stack
+			}
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,133 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rickbassham/logging"
+)
+
+func TestLoggerWithContextKeepsReceiverWriter(t *testing.T) {
+	var dbBuf, reqBuf bytes.Buffer
+
+	dbLogger := logging.NewLogger(&dbBuf, logging.JSONFormatter{}, logging.LogLevelDebug)
+	reqLogger := logging.NewLogger(&reqBuf, logging.JSONFormatter{}, logging.LogLevelDebug).WithField("requestID", "abc")
+
+	ctx := logging.NewContext(context.Background(), reqLogger)
+
+	dbLogger.WithContext(ctx).Info("querying")
+
+	if dbBuf.Len() == 0 {
+		t.Fatalf("expected dbLogger.WithContext(ctx) to write to dbBuf, got nothing")
+	}
+	if reqBuf.Len() != 0 {
+		t.Fatalf("expected dbLogger.WithContext(ctx) not to write to reqBuf, got: %s", reqBuf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(dbBuf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	fields, _ := entry["fields"].(map[string]interface{})
+	if fields["requestID"] != "abc" {
+		t.Errorf("expected inherited requestID field, got fields=%v", fields)
+	}
+}
+
+func TestLogEntryWithContextMergesFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	base := logging.NewLogger(&buf, logging.JSONFormatter{}, logging.LogLevelDebug)
+	parent := base.WithField("requestID", "abc")
+	ctx := logging.NewContext(context.Background(), parent)
+
+	child := base.WithField("component", "db").WithContext(ctx)
+	child.Info("hi")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	fields, _ := entry["fields"].(map[string]interface{})
+	if fields["requestID"] != "abc" || fields["component"] != "db" {
+		t.Errorf("expected both fields merged, got fields=%v", fields)
+	}
+}
+
+func TestLogEntryWithContextLocalFieldWinsOverInherited(t *testing.T) {
+	var buf bytes.Buffer
+
+	base := logging.NewLogger(&buf, logging.JSONFormatter{}, logging.LogLevelDebug)
+	parent := base.WithField("component", "ctx-parent")
+	ctx := logging.NewContext(context.Background(), parent)
+
+	child := base.WithField("component", "local").WithContext(ctx)
+	child.Info("hi")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	fields, _ := entry["fields"].(map[string]interface{})
+	if fields["component"] != "local" {
+		t.Errorf("expected locally attached field to win over inherited context field, got fields=%v", fields)
+	}
+}
+
+func TestLoggerWithContextInheritsStackAlongsideError(t *testing.T) {
+	var dbBuf, reqBuf bytes.Buffer
+
+	dbLogger := logging.NewLogger(&dbBuf, logging.JSONFormatter{}, logging.LogLevelDebug)
+	reqLogger := logging.NewLogger(&reqBuf, logging.JSONFormatter{}, logging.LogLevelDebug).WithError(errors.New("boom"))
+
+	ctx := logging.NewContext(context.Background(), reqLogger)
+
+	dbLogger.WithContext(ctx).Info("querying")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(dbBuf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if entry["error"] != "boom" {
+		t.Errorf("expected inherited error, got entry=%v", entry)
+	}
+	if _, ok := entry["stack"]; !ok {
+		t.Errorf("expected inherited stack alongside the inherited error, got entry=%v", entry)
+	}
+}
+
+func TestLogEntryWithContextInheritsStackAlongsideError(t *testing.T) {
+	var buf bytes.Buffer
+
+	base := logging.NewLogger(&buf, logging.JSONFormatter{}, logging.LogLevelDebug)
+	parent := base.WithError(errors.New("boom"))
+	ctx := logging.NewContext(context.Background(), parent)
+
+	child := base.WithField("component", "db").WithContext(ctx)
+	child.Info("hi")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if entry["error"] != "boom" {
+		t.Errorf("expected inherited error, got entry=%v", entry)
+	}
+	if _, ok := entry["stack"]; !ok {
+		t.Errorf("expected inherited stack alongside the inherited error, got entry=%v", entry)
+	}
+}
+
+func TestFromContextNoLogger(t *testing.T) {
+	if _, ok := logging.FromContext(context.Background()); ok {
+		t.Fatalf("expected no logger in a bare context")
+	}
+}
@@ -0,0 +1,36 @@
+package logging_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/rickbassham/logging"
+)
+
+const fatalChildEnv = "LOGGING_FATAL_CHILD"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fatalChildEnv) == "1" {
+		l := logging.NewAsyncLogger(os.Stdout, logging.JSONFormatter{}, logging.LogLevelDebug, 16, nil)
+		l.Fatal("dying message")
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestAsyncLoggerFatalFlushesBeforeExit(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=TestAsyncLoggerFatalFlushesBeforeExit")
+	cmd.Env = append(os.Environ(), fatalChildEnv+"=1")
+
+	out, err := cmd.Output()
+	if err == nil {
+		t.Fatalf("expected child process to exit non-zero")
+	}
+
+	if !strings.Contains(string(out), "dying message") {
+		t.Fatalf("expected output to contain the fatal message, got: %q", out)
+	}
+}
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// DefaultTimeFormat is used by TextFormatter and ConsoleFormatter when
+// TimeFormat is left blank.
+const DefaultTimeFormat = "2006-01-02T15:04:05.000Z0700"
+
+// TextFormatter renders a logEntry as human-readable logfmt-style
+// key=value pairs, one entry per line.
+type TextFormatter struct {
+	// TimeFormat controls how Timestamp is rendered. Defaults to
+	// DefaultTimeFormat when empty.
+	TimeFormat string
+}
+
+func (f TextFormatter) Format(l *logEntry) (string, error) {
+	layout := f.TimeFormat
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+
+	var buf bytes.Buffer
+
+	writePair(&buf, "timestamp", l.Timestamp.Format(layout))
+	writePair(&buf, "level", l.Level)
+	writePair(&buf, "msg", l.Msg)
+	writePair(&buf, "package", l.Package)
+	writePair(&buf, "function", l.Function)
+	writePair(&buf, "file", l.File)
+	writePair(&buf, "line", l.Line)
+
+	if l.ErrorStr != "" {
+		writePair(&buf, "error", l.ErrorStr)
+	}
+
+	for _, k := range sortedKeys(l.Fields) {
+		writePair(&buf, k, l.Fields[k])
+	}
+
+	return strings.TrimSuffix(buf.String(), " "), nil
+}
+
+// ConsoleFormatter renders a logEntry for an interactive terminal,
+// color-coding the level tag. Colors are automatically disabled when
+// Writer is not a TTY, as detected via mattn/go-isatty.
+type ConsoleFormatter struct {
+	// TimeFormat controls how Timestamp is rendered. Defaults to
+	// DefaultTimeFormat when empty.
+	TimeFormat string
+
+	// Writer is inspected once, on first use, to decide whether color
+	// escapes should be emitted. If nil, colors are disabled.
+	Writer io.Writer
+
+	colorOnce sync.Once
+	noColor   bool
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+func (f *ConsoleFormatter) Format(l *logEntry) (string, error) {
+	layout := f.TimeFormat
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+
+	level := l.Level.String()
+	if !f.colorDisabled() {
+		level = colorize(l.Level) + level + colorReset
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s [%s] %s", l.Timestamp.Format(layout), level, l.Msg)
+
+	for _, k := range sortedKeys(l.Fields) {
+		buf.WriteByte(' ')
+		writePair(&buf, k, l.Fields[k])
+	}
+
+	if l.ErrorStr != "" {
+		buf.WriteByte(' ')
+		writePair(&buf, "error", l.ErrorStr)
+	}
+
+	return strings.TrimSuffix(buf.String(), " "), nil
+}
+
+func (f *ConsoleFormatter) colorDisabled() bool {
+	f.colorOnce.Do(func() {
+		if f.Writer == nil {
+			f.noColor = true
+			return
+		}
+
+		if file, ok := f.Writer.(*os.File); ok {
+			f.noColor = !isatty.IsTerminal(file.Fd())
+		} else {
+			f.noColor = true
+		}
+	})
+
+	return f.noColor
+}
+
+func colorize(level Level) string {
+	switch level {
+	case LevelTrace, LevelDebug:
+		return colorCyan
+	case LevelInfo:
+		return colorBlue
+	case LevelWarn:
+		return colorYellow
+	case LevelError, LevelFatal, LevelPanic:
+		return colorRed
+	default:
+		return colorReset
+	}
+}
+
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writePair(buf *bytes.Buffer, key string, value interface{}) {
+	fmt.Fprintf(buf, "%s=%s ", key, quoteIfNeeded(fmt.Sprintf("%v", value)))
+}
+
+func quoteIfNeeded(s string) string {
+	if strings.ContainsAny(s, " =\"") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
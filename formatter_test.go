@@ -0,0 +1,78 @@
+package logging_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rickbassham/logging"
+)
+
+func TestTextFormatterRendersKeyValuePairs(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.TextFormatter{}, logging.LogLevelDebug)
+
+	l.WithField("user", "ada").Info("hello world")
+
+	out := buf.String()
+	for _, want := range []string{"level=INFO", `msg="hello world"`, "user=ada"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q missing %q", out, want)
+		}
+	}
+}
+
+func TestTextFormatterQuotesValuesWithSpacesOrEquals(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.TextFormatter{}, logging.LogLevelDebug)
+
+	l.WithField("query", "a=b c").Info("run")
+
+	if !strings.Contains(buf.String(), `query="a=b c"`) {
+		t.Errorf("expected quoted value, got: %s", buf.String())
+	}
+}
+
+func TestConsoleFormatterDisablesColorForNonTTY(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, &logging.ConsoleFormatter{Writer: &buf}, logging.LogLevelDebug)
+
+	l.Info("hello")
+
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected no ANSI escapes for a non-TTY writer, got: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "[INFO]") {
+		t.Errorf("expected level tag, got: %q", buf.String())
+	}
+}
+
+func TestConsoleFormatterDisablesColorForNilWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, &logging.ConsoleFormatter{}, logging.LogLevelDebug)
+
+	l.Info("hello")
+
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected no ANSI escapes with a nil Writer, got: %q", buf.String())
+	}
+}
+
+func TestConsoleFormatterColorDisabledIsRaceFree(t *testing.T) {
+	buf := &syncBuffer{}
+	f := &logging.ConsoleFormatter{Writer: buf}
+	l := logging.NewLogger(buf, f, logging.LogLevelDebug)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info("hello")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+// recordingHook records the Level of every entry it is fired for.
+// It lives in an internal test file because Hook.Fire takes the
+// unexported *logEntry type, which a package logging_test file has
+// no way to name.
+type recordingHook struct {
+	levels []Level
+	fired  []Level
+}
+
+func (h *recordingHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *recordingHook) Fire(entry *logEntry) error {
+	h.fired = append(h.fired, entry.Level)
+	return nil
+}
+
+func TestHookOnlyFiresForItsLevels(t *testing.T) {
+	hook := &recordingHook{levels: []Level{LevelWarn, LevelError}}
+
+	l := NewLogger(ioutil.Discard, JSONFormatter{}, LogLevelDebug, WithHook(hook))
+
+	l.Info("ignored")
+	l.Warn("warned")
+	l.Error("errored")
+
+	if len(hook.fired) != 2 || hook.fired[0] != LevelWarn || hook.fired[1] != LevelError {
+		t.Fatalf("expected hook to fire only for LevelWarn and LevelError, got: %v", hook.fired)
+	}
+}
@@ -0,0 +1,71 @@
+package logging
+
+import "fmt"
+
+// Level is the severity of a log entry. Lower values are more verbose.
+type Level int
+
+const (
+	LevelTrace Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+	LevelPanic
+	LevelOff
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "TRACE"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelFatal:
+		return "FATAL"
+	case LevelPanic:
+		return "PANIC"
+	case LevelOff:
+		return "OFF"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalJSON renders a Level as its string name, e.g. "INFO".
+func (l Level) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", l.String())), nil
+}
+
+// ParseLevel parses the string name of a Level, accepting both the new
+// TRACE/DEBUG/... names and the legacy LogLevel* string constants, for
+// use when loading levels from config files.
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case "TRACE":
+		return LevelTrace, nil
+	case LogLevelDebug:
+		return LevelDebug, nil
+	case LogLevelInfo:
+		return LevelInfo, nil
+	case LogLevelWarning, "WARNING":
+		return LevelWarn, nil
+	case LogLevelError:
+		return LevelError, nil
+	case "FATAL":
+		return LevelFatal, nil
+	case "PANIC":
+		return LevelPanic, nil
+	case "OFF":
+		return LevelOff, nil
+	default:
+		return LevelOff, fmt.Errorf("logging: unknown level %q", s)
+	}
+}
@@ -0,0 +1,36 @@
+package logging_test
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/rickbassham/logging"
+)
+
+func TestConcurrentSetLevelAndLog(t *testing.T) {
+	l := logging.NewLogger(ioutil.Discard, logging.JSONFormatter{}, logging.LogLevelDebug)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				l.SetLevel(logging.LevelInfo)
+			}
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				l.Info("hello")
+			}
+		}()
+	}
+
+	wg.Wait()
+}
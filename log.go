@@ -1,15 +1,19 @@
 package logging
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"io/ioutil"
-	"path"
-	"runtime"
-	"strings"
+	"os"
+	"sync/atomic"
 	"time"
 )
 
+// The LogLevel* string constants are kept as thin aliases for
+// backward compatibility with callers that pass a string level to
+// NewLogger. Prefer the Level constants (LevelDebug, etc.) going
+// forward.
 const (
 	LogLevelDebug   = "DEBUG"
 	LogLevelInfo    = "INFO"
@@ -20,17 +24,82 @@ const (
 type Logger interface {
 	WithField(key string, value interface{}) Logger
 	WithError(err error) Logger
+	WithContext(ctx context.Context) Logger
 
-	Info(msg string)
+	Trace(msg string)
 	Debug(msg string)
+	Info(msg string)
 	Warn(msg string)
 	Error(msg string)
+	Fatal(msg string)
+	Panic(msg string)
+
+	SetLevel(level Level)
+	GetLevel() Level
+
+	// Flush blocks until any buffered entries have been delivered.
+	// It is a no-op unless the underlying writer buffers writes
+	// (e.g. an AsyncWriter from NewAsyncLogger).
+	Flush() error
+	// Close flushes and releases the underlying writer, if it
+	// supports it. It is a no-op otherwise.
+	Close() error
+	// Stats reports counters (such as dropped entries) for the
+	// underlying writer. It is the zero Stats unless the underlying
+	// writer tracks them.
+	Stats() Stats
 }
 
 type logger struct {
-	f     Formatter
-	o     io.Writer
-	level string
+	f Formatter
+	o io.Writer
+	// level is read on every Debug/Info/Warn/... call and written by
+	// SetLevel, both possibly from concurrent goroutines, so it is
+	// accessed atomically rather than guarded by a mutex.
+	level int32
+
+	sinks      *MultiSink
+	hooks      []Hook
+	callerSkip int
+}
+
+// SetLevel changes the minimum level l will emit.
+func (l *logger) SetLevel(level Level) {
+	atomic.StoreInt32(&l.level, int32(level))
+}
+
+// GetLevel returns the minimum level l will emit.
+func (l *logger) GetLevel() Level {
+	return Level(atomic.LoadInt32(&l.level))
+}
+
+func (l *logger) Flush() error {
+	if f, ok := l.o.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (l *logger) Close() error {
+	var first error
+	if c, ok := l.o.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			first = err
+		}
+	}
+
+	if err := l.sinks.Close(); err != nil && first == nil {
+		first = err
+	}
+
+	return first
+}
+
+func (l *logger) Stats() Stats {
+	if s, ok := l.o.(interface{ Stats() Stats }); ok {
+		return s.Stats()
+	}
+	return Stats{}
 }
 
 type errorLocation struct {
@@ -43,17 +112,17 @@ type errorLocation struct {
 type logEntry struct {
 	l *logger
 
-	Timestamp     time.Time              `json:"timestamp,omitempty"`
-	Level         string                 `json:"level"`
-	Package       string                 `json:"package"`
-	Function      string                 `json:"function"`
-	File          string                 `json:"file"`
-	Line          int                    `json:"line"`
-	Fields        map[string]interface{} `json:"fields,omitempty"`
-	ErrorStr      string                 `json:"error,omitempty"`
-	ErrorData     error                  `json:"errorData,omitempty"`
-	ErrorLocation *errorLocation         `json:"errorLocation,omitempty"`
-	Msg           string                 `json:"message"`
+	Timestamp time.Time              `json:"timestamp,omitempty"`
+	Level     Level                  `json:"level"`
+	Package   string                 `json:"package"`
+	Function  string                 `json:"function"`
+	File      string                 `json:"file"`
+	Line      int                    `json:"line"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	ErrorStr  string                 `json:"error,omitempty"`
+	ErrorData error                  `json:"errorData,omitempty"`
+	Stack     []errorLocation        `json:"stack,omitempty"`
+	Msg       string                 `json:"message"`
 }
 
 type Formatter interface {
@@ -62,9 +131,10 @@ type Formatter interface {
 
 type JSONFormatter struct{}
 
-func NewLogger(output io.Writer, f Formatter, level string) Logger {
-	if level != LogLevelDebug && level != LogLevelInfo && level != LogLevelWarning && level != LogLevelError {
-		level = LogLevelInfo
+func NewLogger(output io.Writer, f Formatter, level string, opts ...Option) Logger {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		lvl = LevelInfo
 	}
 
 	if output == nil {
@@ -75,11 +145,17 @@ func NewLogger(output io.Writer, f Formatter, level string) Logger {
 		f = JSONFormatter{}
 	}
 
-	return &logger{
+	l := &logger{
 		o:     output,
 		f:     f,
-		level: level,
+		level: int32(lvl),
 	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
 }
 
 func (l *logger) WithField(key string, value interface{}) Logger {
@@ -104,41 +180,48 @@ func (l *logger) WithError(err error) Logger {
 		le.ErrorStr = err.Error()
 	}
 
-	le.setErrorLocation()
+	le.captureStack()
 
 	return &le
 }
 
+func (l *logger) Trace(msg string) {
+	l.log(LevelTrace, msg)
+}
+
 func (l *logger) Debug(msg string) {
-	if l.level == LogLevelError || l.level == LogLevelWarning || l.level == LogLevelInfo {
-		return
-	}
+	l.log(LevelDebug, msg)
+}
 
-	le := &logEntry{
-		l:      l,
-		Fields: map[string]interface{}{},
-	}
+func (l *logger) Info(msg string) {
+	l.log(LevelInfo, msg)
+}
 
-	le.setData(msg, LogLevelDebug)
-	le.write()
+func (l *logger) Warn(msg string) {
+	l.log(LevelWarn, msg)
 }
 
-func (l *logger) Info(msg string) {
-	if l.level == LogLevelError || l.level == LogLevelWarning {
-		return
-	}
+func (l *logger) Error(msg string) {
+	l.log(LevelError, msg)
+}
 
-	le := &logEntry{
-		l:      l,
-		Fields: map[string]interface{}{},
-	}
+func (l *logger) Fatal(msg string) {
+	l.logFinal(LevelFatal, msg)
+	l.Flush()
+	os.Exit(1)
+}
 
-	le.setData(msg, LogLevelInfo)
-	le.write()
+func (l *logger) Panic(msg string) {
+	l.logFinal(LevelPanic, msg)
+	l.Flush()
+	panic(msg)
 }
 
-func (l *logger) Warn(msg string) {
-	if l.level == LogLevelError {
+// logFinal is like log, but for the terminal message a Fatal/Panic
+// call emits right before it flushes and exits: that message must
+// never be the one an overflowing AsyncWriter chooses to drop.
+func (l *logger) logFinal(level Level, msg string) {
+	if level < l.GetLevel() {
 		return
 	}
 
@@ -147,17 +230,21 @@ func (l *logger) Warn(msg string) {
 		Fields: map[string]interface{}{},
 	}
 
-	le.setData(msg, LogLevelWarning)
-	le.write()
+	le.setData(msg, level)
+	le.writeGuaranteed()
 }
 
-func (l *logger) Error(msg string) {
+func (l *logger) log(level Level, msg string) {
+	if level < l.GetLevel() {
+		return
+	}
+
 	le := &logEntry{
 		l:      l,
 		Fields: map[string]interface{}{},
 	}
 
-	le.setData(msg, LogLevelError)
+	le.setData(msg, level)
 	le.write()
 }
 
@@ -167,6 +254,7 @@ func (l *logEntry) newEntry() *logEntry {
 		Fields:    map[string]interface{}{},
 		ErrorData: l.ErrorData,
 		ErrorStr:  l.ErrorStr,
+		Stack:     l.Stack,
 	}
 
 	for k, v := range l.Fields {
@@ -190,23 +278,17 @@ func (l *logEntry) WithError(err error) Logger {
 		e.ErrorStr = err.Error()
 	}
 
-	l.setErrorLocation()
+	e.captureStack()
 
 	return e
 }
 
-func (l *logEntry) setErrorLocation() {
-	loc := retrieveCallInfo()
-	l.ErrorLocation = &errorLocation{
-		File:     loc.fileName,
-		Function: loc.funcName,
-		Line:     loc.line,
-		Package:  loc.packageName,
-	}
+func (l *logEntry) captureStack() {
+	l.Stack = retrieveStack(l.l.callerSkip)
 }
 
-func (l *logEntry) setData(msg, level string) {
-	caller := retrieveCallInfo()
+func (l *logEntry) setData(msg string, level Level) {
+	caller := retrieveCallInfo(l.l.callerSkip)
 
 	l.File = caller.fileName
 	l.Line = caller.line
@@ -217,47 +299,115 @@ func (l *logEntry) setData(msg, level string) {
 	l.Timestamp = time.Now()
 }
 
-func (l *logEntry) Debug(msg string) {
-	if l.l.level == LogLevelError || l.l.level == LogLevelWarning || l.l.level == LogLevelInfo {
+func (l *logEntry) log(level Level, msg string) {
+	if level < l.l.GetLevel() {
 		return
 	}
 
-	l.setData(msg, LogLevelDebug)
+	l.setData(msg, level)
 	l.write()
 }
 
-func (l *logEntry) Info(msg string) {
-	if l.l.level == LogLevelError || l.l.level == LogLevelWarning {
-		return
-	}
+func (l *logEntry) Trace(msg string) {
+	l.log(LevelTrace, msg)
+}
 
-	l.setData(msg, LogLevelInfo)
-	l.write()
+func (l *logEntry) Debug(msg string) {
+	l.log(LevelDebug, msg)
+}
+
+func (l *logEntry) Info(msg string) {
+	l.log(LevelInfo, msg)
 }
 
 func (l *logEntry) Warn(msg string) {
-	if l.l.level == LogLevelError {
+	l.log(LevelWarn, msg)
+}
+
+func (l *logEntry) Error(msg string) {
+	l.log(LevelError, msg)
+}
+
+func (l *logEntry) Fatal(msg string) {
+	l.logFinal(LevelFatal, msg)
+	l.l.Flush()
+	os.Exit(1)
+}
+
+func (l *logEntry) Panic(msg string) {
+	l.logFinal(LevelPanic, msg)
+	l.l.Flush()
+	panic(msg)
+}
+
+// logFinal is like log, but for the terminal message a Fatal/Panic
+// call emits right before it flushes and exits: that message must
+// never be the one an overflowing AsyncWriter chooses to drop.
+func (l *logEntry) logFinal(level Level, msg string) {
+	if level < l.l.GetLevel() {
 		return
 	}
 
-	l.setData(msg, LogLevelWarning)
-	l.write()
+	l.setData(msg, level)
+	l.writeGuaranteed()
 }
 
-func (l *logEntry) Error(msg string) {
-	l.setData(msg, LogLevelError)
-	l.write()
+func (l *logEntry) SetLevel(level Level) {
+	l.l.SetLevel(level)
+}
+
+func (l *logEntry) GetLevel() Level {
+	return l.l.GetLevel()
+}
+
+func (l *logEntry) Flush() error {
+	return l.l.Flush()
+}
+
+func (l *logEntry) Close() error {
+	return l.l.Close()
+}
+
+func (l *logEntry) Stats() Stats {
+	return l.l.Stats()
 }
 
 func (l *logEntry) write() {
+	l.l.o.Write(l.format())
+
+	l.l.sinks.dispatch(l)
+	fireHooks(l.l.hooks, l)
+}
+
+// writeGuaranteed is like write, but delivers through the underlying
+// writer's guaranteed-delivery path when it has one (see
+// AsyncWriter.WriteGuaranteed), instead of whatever overflow policy
+// the writer was configured with.
+func (l *logEntry) writeGuaranteed() {
+	data := l.format()
+
+	if gw, ok := l.l.o.(interface {
+		WriteGuaranteed([]byte) (int, error)
+	}); ok {
+		gw.WriteGuaranteed(data)
+	} else {
+		l.l.o.Write(data)
+	}
+
+	l.l.sinks.dispatch(l)
+	fireHooks(l.l.hooks, l)
+}
+
+func (l *logEntry) format() []byte {
 	output, err := l.l.f.Format(l)
 	if err != nil {
-		l.l.o.Write([]byte("error marshalling logEntry"))
-		return
+		return []byte("error marshalling logEntry")
 	}
 
-	l.l.o.Write([]byte(output))
-	l.l.o.Write([]byte("\n"))
+	// A single Write call so an AsyncWriter never splits one entry's
+	// payload and trailing newline across two separately droppable
+	// queued messages.
+	return []byte(output + "\n")
 }
 
 func (JSONFormatter) Format(l *logEntry) (string, error) {
@@ -268,33 +418,3 @@ func (JSONFormatter) Format(l *logEntry) (string, error) {
 
 	return string(data), nil
 }
-
-type callInfo struct {
-	packageName string
-	fileName    string
-	funcName    string
-	line        int
-}
-
-func retrieveCallInfo() *callInfo {
-	pc, file, line, _ := runtime.Caller(3)
-	_, fileName := path.Split(file)
-	parts := strings.Split(runtime.FuncForPC(pc).Name(), ".")
-	pl := len(parts)
-	packageName := ""
-	funcName := parts[pl-1]
-
-	if parts[pl-2][0] == '(' {
-		funcName = parts[pl-2] + "." + funcName
-		packageName = strings.Join(parts[0:pl-2], ".")
-	} else {
-		packageName = strings.Join(parts[0:pl-1], ".")
-	}
-
-	return &callInfo{
-		packageName: packageName,
-		fileName:    fileName,
-		funcName:    funcName,
-		line:        line,
-	}
-}
@@ -0,0 +1,85 @@
+package logging_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rickbassham/logging"
+)
+
+// closeTrackingWriter records whether Close was called, so tests can
+// assert that a sink registered via WithSink is actually closed.
+type closeTrackingWriter struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *closeTrackingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+// syncBuffer wraps bytes.Buffer to make Write safe for the
+// AsyncWriter's dedicated delivery goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLoggerCloseClosesRegisteredSinks(t *testing.T) {
+	sinkWriter := &closeTrackingWriter{}
+	sinkAW := logging.NewAsyncWriter(sinkWriter, 16, logging.OverflowDropOldest, nil)
+
+	l := logging.NewLogger(ioutil.Discard, logging.JSONFormatter{}, logging.LogLevelDebug,
+		logging.WithSink(logging.Sink{Writer: sinkAW, Formatter: logging.JSONFormatter{}, Level: logging.LevelDebug}))
+
+	l.Info("hello")
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if !sinkWriter.closed {
+		t.Fatalf("expected Close to close the sink's AsyncWriter and its underlying writer")
+	}
+}
+
+func TestAsyncLoggerNeverSplitsAnEntryAcrossWrites(t *testing.T) {
+	buf := &syncBuffer{}
+	l := logging.NewAsyncLogger(buf, logging.JSONFormatter{}, logging.LogLevelDebug, 1, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info("hello")
+		}()
+	}
+	wg.Wait()
+	l.Flush()
+
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+			t.Fatalf("entry split across writes, got partial line: %q", line)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package logging_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rickbassham/logging"
+)
+
+func TestMultiSinkSkipsEntriesBelowSinkLevel(t *testing.T) {
+	var mainBuf, sinkBuf bytes.Buffer
+
+	l := logging.NewLogger(&mainBuf, logging.JSONFormatter{}, logging.LogLevelDebug,
+		logging.WithSink(logging.Sink{Writer: &sinkBuf, Formatter: logging.JSONFormatter{}, Level: logging.LevelWarn}))
+
+	l.Info("below the sink's level")
+
+	if mainBuf.Len() == 0 {
+		t.Fatalf("expected the main logger to still emit the entry")
+	}
+	if sinkBuf.Len() != 0 {
+		t.Fatalf("expected the sink to skip an entry below its Level, got: %s", sinkBuf.String())
+	}
+
+	l.Warn("at the sink's level")
+
+	if sinkBuf.Len() == 0 {
+		t.Fatalf("expected the sink to receive an entry at its Level")
+	}
+}
@@ -0,0 +1,47 @@
+package logging
+
+// Option configures a Logger constructed by NewLogger or
+// NewAsyncLogger.
+type Option func(*logger)
+
+// WithSink adds an additional destination that every logEntry is
+// fanned out to, independent of the Logger's primary writer.
+func WithSink(sink Sink) Option {
+	return func(l *logger) {
+		if l.sinks == nil {
+			l.sinks = NewMultiSink()
+		}
+		l.sinks.Add(sink)
+	}
+}
+
+// WithHook registers a Hook that is fired for every logEntry whose
+// Level matches one of h.Levels().
+func WithHook(h Hook) Option {
+	return func(l *logger) {
+		l.hooks = append(l.hooks, h)
+	}
+}
+
+// WithLevel overrides the minimum Level the Logger will emit.
+func WithLevel(level Level) Option {
+	return func(l *logger) {
+		l.SetLevel(level)
+	}
+}
+
+// WithFormatter overrides the Logger's primary Formatter.
+func WithFormatter(f Formatter) Option {
+	return func(l *logger) {
+		l.f = f
+	}
+}
+
+// WithCallerSkip skips n additional frames when resolving the caller
+// of a log call, for packages that wrap this Logger in their own
+// helper functions.
+func WithCallerSkip(n int) Option {
+	return func(l *logger) {
+		l.callerSkip = n
+	}
+}
@@ -0,0 +1,273 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+const dailyTimeFormat = "2006-01-02"
+
+// RotatingFileWriter is an io.Writer that rotates the file it writes
+// to once it reaches MaxSize bytes, or once a day when Daily is set,
+// keeping at most MaxBackups rotated segments no older than MaxAge
+// days, optionally gzip-compressed. It is safe for concurrent Write
+// calls.
+type RotatingFileWriter struct {
+	// Filename is the path writes are appended to. Rotated segments
+	// are written alongside it.
+	Filename string
+	// MaxSize is the size in bytes a file may reach before it is
+	// rotated. Zero disables size-based rotation.
+	MaxSize int64
+	// MaxAge is how many days to keep rotated segments. Zero keeps
+	// them forever.
+	MaxAge int
+	// MaxBackups is how many rotated segments to keep. Zero keeps
+	// them all.
+	MaxBackups int
+	// Compress gzips rotated segments.
+	Compress bool
+	// Daily rotates once per day (named filename.2006-01-02.log)
+	// instead of by size.
+	Daily bool
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	openDay string
+}
+
+// NewRotatingFileWriter opens (or creates) filename and returns a
+// RotatingFileWriter that rotates it once it reaches maxSize bytes,
+// keeping at most maxBackups segments no older than maxAge days,
+// gzip-compressing them when compress is true.
+func NewRotatingFileWriter(filename string, maxSize int64, maxAge, maxBackups int, compress bool) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		Filename:   filename,
+		MaxSize:    maxSize,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+		Compress:   compress,
+	}
+
+	if err := w.openExisting(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// NewDailyRotatingFileWriter is like NewRotatingFileWriter, but rolls
+// the file over once per day instead of at a size threshold.
+func NewDailyRotatingFileWriter(filename string, maxAge, maxBackups int, compress bool) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		Filename:   filename,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+		Compress:   compress,
+		Daily:      true,
+	}
+
+	if err := w.openExisting(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *RotatingFileWriter) openExisting() error {
+	f, err := os.OpenFile(w.Filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.size = info.Size()
+	w.openDay = time.Now().Format(dailyTimeFormat)
+
+	return nil
+}
+
+// Write appends p to the current file, rotating first if p would push
+// the file over MaxSize, or if Daily is set and the day has changed.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.needsRotationLocked(len(p)) {
+		if err := w.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+func (w *RotatingFileWriter) needsRotationLocked(writeLen int) bool {
+	if w.Daily && time.Now().Format(dailyTimeFormat) != w.openDay {
+		return true
+	}
+
+	if w.MaxSize > 0 && w.size+int64(writeLen) > w.MaxSize {
+		return true
+	}
+
+	return false
+}
+
+// Rotate closes the current file, renames it to a timestamped (or
+// dated, in Daily mode) backup, and reopens Filename, enforcing
+// MaxBackups and MaxAge. It is exported so callers can trigger
+// rotation externally, e.g. from a SIGHUP handler.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.rotateLocked()
+}
+
+func (w *RotatingFileWriter) rotateLocked() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(w.Filename); err == nil {
+		backup := w.backupName()
+
+		if err := os.Rename(w.Filename, backup); err != nil {
+			return err
+		}
+
+		if w.Compress {
+			if err := compressFile(backup); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.openExisting(); err != nil {
+		return err
+	}
+
+	return w.pruneLocked()
+}
+
+func (w *RotatingFileWriter) backupName() string {
+	dir := filepath.Dir(w.Filename)
+	base := filepath.Base(w.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext)
+
+	if w.Daily {
+		return filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, w.openDay, ext))
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, time.Now().Format(backupTimeFormat), ext))
+}
+
+func compressFile(name string) error {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	gzFile, err := os.Create(name + ".gz")
+	if err != nil {
+		return err
+	}
+	defer gzFile.Close()
+
+	gw := gzip.NewWriter(gzFile)
+	if _, err := gw.Write(data); err != nil {
+		gw.Close()
+		return err
+	}
+
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+func (w *RotatingFileWriter) pruneLocked() error {
+	dir := filepath.Dir(w.Filename)
+	base := filepath.Base(w.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext)
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []os.FileInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == base || !strings.HasPrefix(name, prefix+".") {
+			continue
+		}
+
+		backups = append(backups, entry)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().Before(backups[j].ModTime())
+	})
+
+	if w.MaxAge > 0 {
+		cutoff := time.Now().AddDate(0, 0, -w.MaxAge)
+
+		kept := backups[:0]
+		for _, b := range backups {
+			if b.ModTime().Before(cutoff) {
+				os.Remove(filepath.Join(dir, b.Name()))
+				continue
+			}
+			kept = append(kept, b)
+		}
+		backups = kept
+	}
+
+	if w.MaxBackups > 0 && len(backups) > w.MaxBackups {
+		for _, b := range backups[:len(backups)-w.MaxBackups] {
+			os.Remove(filepath.Join(dir, b.Name()))
+		}
+	}
+
+	return nil
+}
+
+// Close closes the current file.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	return w.file.Close()
+}
+
+var _ io.WriteCloser = (*RotatingFileWriter)(nil)
@@ -0,0 +1,206 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func backupFiles(t *testing.T, dir, base string) []string {
+	t.Helper()
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.Name() != base {
+			names = append(names, e.Name())
+		}
+	}
+
+	return names
+}
+
+func TestRotatingFileWriterRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingFileWriter(filename, 10, 0, 0, false)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("12345")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Write([]byte("67890")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// This write pushes the file past MaxSize, so it rotates first.
+	if _, err := w.Write([]byte("rotateme")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	names := backupFiles(t, dir, "app.log")
+	if len(names) != 1 {
+		t.Fatalf("expected exactly one backup after rotation, got %v", names)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "rotateme" {
+		t.Fatalf("expected the active file to contain only the post-rotation write, got %q", data)
+	}
+}
+
+func TestRotatingFileWriterRotatesOnDayChange(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewDailyRotatingFileWriter(filename, 0, 0, false)
+	if err != nil {
+		t.Fatalf("NewDailyRotatingFileWriter: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("yesterday's entry")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// Simulate the day having changed since the file was opened.
+	w.mu.Lock()
+	w.openDay = time.Now().AddDate(0, 0, -1).Format(dailyTimeFormat)
+	w.mu.Unlock()
+
+	if _, err := w.Write([]byte("today's entry")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	names := backupFiles(t, dir, "app.log")
+	if len(names) != 1 {
+		t.Fatalf("expected exactly one dated backup after the day changed, got %v", names)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "today's entry" {
+		t.Fatalf("expected the active file to contain only today's write, got %q", data)
+	}
+}
+
+func TestRotatingFileWriterMaxBackupsKeepsExactlyN(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingFileWriter(filename, 1, 0, 2, false)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		// Each backup needs a distinct, increasing name/mtime so
+		// pruning has a stable oldest-first order to trim.
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	names := backupFiles(t, dir, "app.log")
+	if len(names) != 2 {
+		t.Fatalf("expected MaxBackups to keep exactly 2 backups, got %v", names)
+	}
+}
+
+func TestRotatingFileWriterMaxAgeRemovesStaleBackups(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingFileWriter(filename, 0, 1, 0, false)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter: %v", err)
+	}
+	defer w.Close()
+
+	stale := filepath.Join(dir, "app.2020-01-01T00-00-00.000.log")
+	if err := ioutil.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	old := time.Now().AddDate(0, 0, -30)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("expected MaxAge to remove the stale backup, stat err: %v", err)
+	}
+}
+
+func TestRotatingFileWriterCompressProducesReadableGzipAndRemovesPlain(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingFileWriter(filename, int64(len("first segment")), 0, 0, true)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("first segment")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// Pushes the file over MaxSize, rotating and compressing "first segment".
+	if _, err := w.Write([]byte("second segment")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	names := backupFiles(t, dir, "app.log")
+	if len(names) != 1 {
+		t.Fatalf("expected exactly one backup, got %v", names)
+	}
+	if filepath.Ext(names[0]) != ".gz" {
+		t.Fatalf("expected the backup to be gzip-compressed, got %v", names)
+	}
+
+	plain := names[0][:len(names[0])-len(".gz")]
+	if _, err := os.Stat(filepath.Join(dir, plain)); !os.IsNotExist(err) {
+		t.Fatalf("expected the uncompressed backup to be removed, stat err: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, names[0]))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gr.Close()
+
+	data, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("reading gzip contents: %v", err)
+	}
+	if string(data) != "first segment" {
+		t.Fatalf("expected gzip contents to be the rotated segment, got %q", data)
+	}
+}